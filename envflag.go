@@ -26,17 +26,58 @@ var exit = func() {
 	os.Exit(2)
 }
 
+// Source is a value source that BindFlagSet can layer onto a flag.FlagSet, such as environment variables or a
+// configuration file. Sources are applied in order; a later source's value for a given flag overrides an earlier
+// one, matching the precedence rules used for multiple prefixes.
+type Source interface {
+	// prepare loads any underlying data the source needs (e.g. reading and parsing a config file). It's called once
+	// per BindFlagSet invocation before any flag is visited.
+	prepare() error
+	// lookup returns the raw string value for the flag and whether the source provides one.
+	lookup(f *flag.Flag) (value string, ok bool)
+	// lookupSlice returns the raw string elements to append to a SliceValue flag, and whether the source provides
+	// any.
+	lookupSlice(f *flag.Flag) (values []string, ok bool)
+	// usageName returns the source-specific name to include in a flag's usage string (e.g. "MY_VAR").
+	usageName(f *flag.Flag) string
+	// describe identifies the source and flag in error messages, e.g. "environment variable MY_VAR".
+	describe(f *flag.Flag) string
+}
+
+// SliceValue is implemented by flag.Value types that hold a slice of values, such as a string-slice flag. Binding a
+// SliceValue flag calls Replace once per contributing source, with that source's full set of elements, so a
+// source's value replaces whatever was there before (including the flag's default) rather than appending to it.
+// This keeps the same "later source wins" precedence used for scalar flags.
+type SliceValue interface {
+	flag.Value
+	// Replace discards any existing elements and sets them to values.
+	Replace(values []string) error
+}
+
 // Prefix is a string prefix that's applied to environment variables. For example, the prefix "MYAPP" will map the
-// environment variable "MYAPP_PORT" to the flag "port".
+// environment variable "MYAPP_PORT" to the flag "port". Prefix implements Source, so it can be passed directly to
+// BindFlagSet, or wrapped with FromEnv for symmetry with other sources.
 type Prefix struct {
-	prefix string
-	strict bool
+	prefix        string
+	strict        bool
+	listSeparator string
+	separator     string
+	mapper        NameMapper
+	known         map[string]string // env var name -> flag name, populated as flags are bound
+}
+
+// NameMapper converts a flag name (e.g. "my-var") to the name used to look it up in a Source, before the prefix and
+// separator are applied. The default mapper upper-cases the name and replaces "-" with "_".
+type NameMapper func(flagName string) string
+
+func defaultNameMapper(flagName string) string {
+	return strings.ReplaceAll(strings.ToUpper(flagName), "-", "_")
 }
 
 // NewPrefix creates a prefix with the provided string and strictness. Binding a strict prefix will return an error if
 // there are any environment variables matching the prefix that do not have corresponding defined flag.
 func NewPrefix(prefix string, opts ...Option) *Prefix {
-	p := &Prefix{prefix: prefix}
+	p := &Prefix{prefix: prefix, listSeparator: ",", separator: "_", mapper: defaultNameMapper}
 	for _, o := range opts {
 		o(p)
 	}
@@ -54,90 +95,301 @@ func Strict(strict bool) Option {
 	}
 }
 
+// WithListSeparator changes the separator used to split a single environment variable into elements for a
+// SliceValue flag. The default separator is a comma, e.g. MYAPP_TAGS=a,b,c.
+func WithListSeparator(sep string) Option {
+	return func(o *Prefix) {
+		o.listSeparator = sep
+	}
+}
+
+// WithSeparator changes the separator placed between the prefix and the mapped flag name. The default is "_", e.g.
+// prefix "MYAPP" and flag "port" produce "MYAPP_PORT"; WithSeparator("__") would produce "MYAPP__PORT".
+func WithSeparator(sep string) Option {
+	return func(o *Prefix) {
+		o.separator = sep
+	}
+}
+
+// WithNameMapper overrides how a flag name is converted to the name looked up under this prefix, in place of the
+// default upper-case-and-underscore rule. The mapper runs before the prefix is applied, so it should return the bare
+// mapped name (e.g. "Port"), not the prefixed one (e.g. "MYAPP_PORT").
+func WithNameMapper(mapper NameMapper) Option {
+	return func(o *Prefix) {
+		o.mapper = mapper
+	}
+}
+
 // NoPrefix matches all environment variables. Unknown environment variables are ignored.
 var NoPrefix = NewPrefix("")
 
-func (p Prefix) envName(flagName string) string {
-	s := strings.ToUpper(flagName)
+// FromEnv returns prefix as a Source. It exists for symmetry with FromFile, since *Prefix already satisfies Source
+// on its own.
+func FromEnv(prefix *Prefix) Source {
+	return prefix
+}
+
+func (p *Prefix) envName(flagName string) string {
+	s := p.mapper(flagName)
 	if p.prefix != "" {
-		s = fmt.Sprintf("%s_%s", p.prefix, s)
+		s = fmt.Sprintf("%s%s%s", p.prefix, p.separator, s)
+	}
+	return s
+}
+
+// FlagName returns the flag name that maps to envVar under this prefix, and whether envVar corresponds to a flag
+// that has actually been bound (via BindFlagSet or BindFlagSetAfterParse). It's meant for diagnostics, e.g. a strict
+// prefix reporting an unknown environment variable can use it to check whether the variable is simply misspelled.
+func (p *Prefix) FlagName(envVar string) (string, bool) {
+	name, ok := p.known[envVar]
+	return name, ok
+}
+
+func (p *Prefix) noteFlag(f *flag.Flag) {
+	if p.known == nil {
+		p.known = make(map[string]string)
+	}
+	p.known[p.envName(f.Name)] = f.Name
+}
+
+func (p *Prefix) prepare() error {
+	return nil
+}
+
+func (p *Prefix) lookup(f *flag.Flag) (string, bool) {
+	return p.Lookup(f.Name)
+}
+
+// lookupSlice reads a slice flag's elements either from a single separator-delimited environment variable (e.g.
+// MYAPP_TAGS=a,b,c) or, if that's unset, from indexed variables (MYAPP_TAGS_0=a, MYAPP_TAGS_1=b, ...).
+func (p *Prefix) lookupSlice(f *flag.Flag) ([]string, bool) {
+	return p.LookupSlice(f.Name)
+}
+
+// EnvName returns the environment variable name that flagName maps to under this prefix.
+func (p *Prefix) EnvName(flagName string) string {
+	return p.envName(flagName)
+}
+
+// Lookup returns the raw environment variable value for flagName under this prefix, and whether it's set. It's
+// exported so that adapters for flag set implementations other than the standard library flag.FlagSet (see
+// envflag/pflag) can reuse Prefix's env-var resolution without duplicating it.
+func (p *Prefix) Lookup(flagName string) (string, bool) {
+	return os.LookupEnv(p.envName(flagName))
+}
+
+// LookupSlice is the slice-flag equivalent of Lookup; see SliceValue.
+func (p *Prefix) LookupSlice(flagName string) ([]string, bool) {
+	names, indexed := p.sliceEnvNames(flagName)
+	if names == nil {
+		return nil, false
+	}
+	if !indexed {
+		v, _ := os.LookupEnv(names[0])
+		return strings.Split(v, p.listSeparator), true
+	}
+	elems := make([]string, len(names))
+	for i, name := range names {
+		elems[i], _ = os.LookupEnv(name)
+	}
+	return elems, true
+}
+
+// SliceEnvNames returns the environment variable name(s) that LookupSlice actually consulted for flagName: the
+// single comma-form name if it was set, or every indexed name found otherwise. It's meant for diagnostics, e.g.
+// marking environment variables as matched before a strict prefix scans for ones that don't correspond to a flag.
+func (p *Prefix) SliceEnvNames(flagName string) []string {
+	names, _ := p.sliceEnvNames(flagName)
+	return names
+}
+
+// sliceEnvNames returns the environment variable name(s) backing flagName's slice value, and whether they're the
+// indexed form (NAME_0, NAME_1, ...) rather than the single comma-separated form.
+func (p *Prefix) sliceEnvNames(flagName string) (names []string, indexed bool) {
+	name := p.envName(flagName)
+	if _, ok := os.LookupEnv(name); ok {
+		return []string{name}, false
+	}
+	for i := 0; ; i++ {
+		indexedName := fmt.Sprintf("%s_%d", name, i)
+		if _, ok := os.LookupEnv(indexedName); !ok {
+			break
+		}
+		names = append(names, indexedName)
+	}
+	if names == nil {
+		return nil, false
 	}
-	return strings.ReplaceAll(s, "-", "_")
+	return names, true
+}
+
+// IsStrict reports whether the prefix was created with Strict(true).
+func (p *Prefix) IsStrict() bool {
+	return p.strict
+}
+
+// HasEnvPrefix reports whether envVar could plausibly belong to this prefix. It's used when scanning os.Environ()
+// for variables that don't correspond to a defined flag under a strict prefix.
+func (p *Prefix) HasEnvPrefix(envVar string) bool {
+	return strings.HasPrefix(envVar, p.prefix)
+}
+
+func (p *Prefix) usageName(f *flag.Flag) string {
+	return p.envName(f.Name)
+}
+
+func (p *Prefix) describe(f *flag.Flag) string {
+	return "environment variable " + p.envName(f.Name)
 }
 
 // Bind binds environment variables to the default flag.CommandLine flag set.
-func Bind(prefixes ...*Prefix) {
-	_ = BindFlagSet(flag.CommandLine, prefixes...)
-}
-
-// BindFlagSet binds environment variables to the provided flag set. If there's an error parsing the environment
-// variables, the error will be handled using the flag set's configured flag.ErrorHandling.
-func BindFlagSet(flagSet *flag.FlagSet, prefixes ...*Prefix) error {
-	if err := bind(flagSet, prefixes); err != nil {
-		switch flagSet.ErrorHandling() {
-		case flag.ContinueOnError:
-			return err
-		case flag.PanicOnError:
-			panic(err)
-		default:
-			fmt.Fprintln(flagSet.Output(), err)
-			if flagSet.Name() == "" {
-				fmt.Fprintf(flagSet.Output(), "Usage:\n")
-			} else {
-				fmt.Fprintf(flagSet.Output(), "Usage of %s:\n", flagSet.Name())
-			}
-			flagSet.PrintDefaults()
-			exit()
+func Bind(sources ...Source) {
+	_ = BindFlagSet(flag.CommandLine, sources...)
+}
+
+// BindFlagSet binds the provided sources (environment variables, config files, ...) to the provided flag set. It
+// must be called before flagSet.Parse(), so that command-line flags take precedence over every source. If there's
+// an error applying a source, the error is handled using the flag set's configured flag.ErrorHandling.
+func BindFlagSet(flagSet *flag.FlagSet, sources ...Source) error {
+	return handleBindError(flagSet, bind(flagSet, sources))
+}
+
+// BindFlagSetAfterParse binds the provided sources to a flag set that has already been parsed with flagSet.Parse().
+// Unlike BindFlagSet, a source's value is only applied to flags that were not explicitly set on the command line,
+// so command-line flags always take precedence over sources regardless of call order. This suits applications that
+// want CLI > source > default precedence, or that only know their sources once startup is underway.
+func BindFlagSetAfterParse(flagSet *flag.FlagSet, sources ...Source) error {
+	return handleBindError(flagSet, bindAfterParse(flagSet, sources))
+}
+
+func handleBindError(flagSet *flag.FlagSet, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch flagSet.ErrorHandling() {
+	case flag.ContinueOnError:
+		return err
+	case flag.PanicOnError:
+		panic(err)
+	default:
+		fmt.Fprintln(flagSet.Output(), err)
+		if flagSet.Name() == "" {
+			fmt.Fprintf(flagSet.Output(), "Usage:\n")
+		} else {
+			fmt.Fprintf(flagSet.Output(), "Usage of %s:\n", flagSet.Name())
 		}
+		flagSet.PrintDefaults()
+		exit()
 	}
 	return nil
 }
 
-func updateUsage(flag *flag.Flag, prefixes []*Prefix) {
-	var envs []string
-	for _, p := range prefixes {
-		envs = append(envs, p.envName(flag.Name))
+func updateUsage(flag *flag.Flag, sources []Source) {
+	var names []string
+	for _, s := range sources {
+		names = append(names, s.usageName(flag))
 	}
-	flag.Usage = fmt.Sprintf("%s [%s]", flag.Usage, strings.Join(envs, ", "))
+	flag.Usage = fmt.Sprintf("%s [%s]", flag.Usage, strings.Join(names, ", "))
 }
 
-func bind(flagSet *flag.FlagSet, prefixes []*Prefix) error {
-	if len(prefixes) == 0 {
-		prefixes = []*Prefix{NoPrefix}
-	}
+func bind(flagSet *flag.FlagSet, sources []Source) error {
 	if flagSet.Parsed() {
 		return fmt.Errorf("envflag.Bind() must be called before flag.Parse()")
 	}
+	return doBind(flagSet, sources, nil)
+}
+
+func bindAfterParse(flagSet *flag.FlagSet, sources []Source) error {
+	if !flagSet.Parsed() {
+		return fmt.Errorf("envflag.BindFlagSetAfterParse() must be called after flag.Parse()")
+	}
+	explicit := make(map[string]bool)
+	flagSet.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	return doBind(flagSet, sources, explicit)
+}
+
+// doBind applies sources to flagSet. A flag whose name is present (and true) in skip is left untouched; this is how
+// bindAfterParse avoids overriding flags already set on the command line.
+func doBind(flagSet *flag.FlagSet, sources []Source, skip map[string]bool) error {
+	if len(sources) == 0 {
+		sources = []Source{NoPrefix}
+	}
+	var problems []BindProblem
+	prepared := make([]Source, 0, len(sources))
+	for _, s := range sources {
+		if err := s.prepare(); err != nil {
+			problems = append(problems, BindProblem{Err: err})
+			continue
+		}
+		prepared = append(prepared, s)
+	}
+	sources = prepared
 	matchedEnvValues := make(map[string]bool)
-	var visitErr error
 	flagSet.VisitAll(func(f *flag.Flag) {
-		updateUsage(f, prefixes)
-		for _, p := range prefixes {
-			e := p.envName(f.Name)
-			if envValue, ok := os.LookupEnv(e); ok {
-				matchedEnvValues[e] = true
-				prevValue := f.Value.String()
-				if err := f.Value.Set(envValue); err != nil {
-					visitErr = fmt.Errorf("invalid value %q for environment variable %s: %v", envValue, e, err)
-					_ = f.Value.Set(prevValue)
+		updateUsage(f, sources)
+		for _, s := range sources {
+			if p, isEnv := s.(*Prefix); isEnv {
+				p.noteFlag(f)
+			}
+		}
+		if skip[f.Name] {
+			return
+		}
+		sliceValue, isSlice := f.Value.(SliceValue)
+		var contribs []sourceContribution
+		for _, s := range sources {
+			if isSlice {
+				elems, ok := s.lookupSlice(f)
+				if !ok {
+					continue
 				}
-				f.Value.String()
+				if p, isEnv := s.(*Prefix); isEnv {
+					for _, name := range p.SliceEnvNames(f.Name) {
+						matchedEnvValues[name] = true
+					}
+				}
+				contribs = append(contribs, sourceContribution{s, strings.Join(elems, ",")})
+				if err := sliceValue.Replace(elems); err != nil {
+					problems = append(problems, BindProblem{Flag: f.Name, Source: s.describe(f), Value: strings.Join(elems, ","), Err: err})
+				}
+				continue
+			}
+			value, ok := s.lookup(f)
+			if !ok {
+				continue
+			}
+			if p, isEnv := s.(*Prefix); isEnv {
+				matchedEnvValues[p.envName(f.Name)] = true
 			}
+			contribs = append(contribs, sourceContribution{s, value})
+			prevValue := f.Value.String()
+			if err := f.Value.Set(value); err != nil {
+				problems = append(problems, BindProblem{Flag: f.Name, Source: s.describe(f), Value: value, Err: err})
+				_ = f.Value.Set(prevValue)
+			}
+		}
+		if problem := conflictProblem(f, contribs); problem != nil {
+			problems = append(problems, *problem)
 		}
 	})
-	if visitErr != nil {
-		return visitErr
-	}
 	for _, e := range os.Environ() {
-		for _, p := range prefixes {
-			if !p.strict {
+		for _, s := range sources {
+			p, ok := s.(*Prefix)
+			if !ok || !p.strict {
 				continue
 			}
 			envName := strings.Split(e, "=")[0]
 			if strings.HasPrefix(e, p.prefix) && !matchedEnvValues[envName] {
-				return fmt.Errorf("environment variable provided but corresponding flag not defined: %s", strings.Split(e, "=")[0])
+				problems = append(problems, BindProblem{
+					Err: fmt.Errorf("environment variable provided but corresponding flag not defined: %s", envName),
+				})
 			}
 		}
 	}
-	return nil
+	if len(problems) == 0 {
+		return nil
+	}
+	return &BindError{Problems: problems}
 }
@@ -15,8 +15,10 @@
 package envflag
 
 import (
+	"errors"
 	"flag"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -26,12 +28,12 @@ func TestBindSuccess(t *testing.T) {
 		description string
 		env         map[string]string
 		cmdLine     []string
-		prefixes    []*Prefix
+		sources     []Source
 		want        string
 	}{
 		{
 			description: "value from environment",
-			prefixes: []*Prefix{AllEnv},
+			sources:     []Source{NoPrefix},
 			env: map[string]string{
 				"MY_VAR": "env value",
 			},
@@ -39,13 +41,13 @@ func TestBindSuccess(t *testing.T) {
 		},
 		{
 			description: "value from command line",
-			prefixes: []*Prefix{AllEnv},
+			sources:     []Source{NoPrefix},
 			cmdLine:     []string{"--my-var=cmd value"},
 			want:        "cmd value",
 		},
 		{
 			description: "value provided on both cmdLine and in env",
-			prefixes: []*Prefix{AllEnv},
+			sources:     []Source{NoPrefix},
 			env: map[string]string{
 				"MY_VAR": "env value",
 			},
@@ -54,12 +56,12 @@ func TestBindSuccess(t *testing.T) {
 		},
 		{
 			description: "default value",
-			prefixes: []*Prefix{AllEnv},
+			sources:     []Source{NoPrefix},
 			want:        "default value",
 		},
 		{
 			description: "value from environment with prefix",
-			prefixes:    []*Prefix{NewPrefix(""), NewPrefix("MYAPP")},
+			sources:     []Source{NewPrefix(""), NewPrefix("MYAPP")},
 			env: map[string]string{
 				"MYAPP_MY_VAR": "env value",
 			},
@@ -67,7 +69,7 @@ func TestBindSuccess(t *testing.T) {
 		},
 		{
 			description: "multiple prefixes",
-			prefixes:    []*Prefix{NewPrefix(""), NewPrefix("MYAPP")},
+			sources:     []Source{NewPrefix(""), NewPrefix("MYAPP")},
 			env: map[string]string{
 				"MY_VAR":       "env value 1",
 				"MYAPP_MY_VAR": "env value 2",
@@ -77,7 +79,7 @@ func TestBindSuccess(t *testing.T) {
 		},
 		{
 			description: "provided but not defined in non-strict prefix",
-			prefixes:    []*Prefix{NewPrefix("MYAPP")},
+			sources:     []Source{NewPrefix("MYAPP")},
 			env: map[string]string{
 				"MYAPP_BAD_NAME": "test123",
 			},
@@ -85,7 +87,7 @@ func TestBindSuccess(t *testing.T) {
 		},
 		{
 			description: "environment value with =",
-			prefixes:    []*Prefix{NewPrefix("MYAPP")},
+			sources:     []Source{NewPrefix("MYAPP")},
 			env: map[string]string{
 				"MYAPP_MY_VAR": "a=b",
 			},
@@ -102,7 +104,7 @@ func TestBindSuccess(t *testing.T) {
 			}
 			fs := flag.NewFlagSet("test", flag.ContinueOnError)
 			testString := fs.String("my-var", "default value", "This is a flag.")
-			if err := BindFlagSet(fs, tc.prefixes...); err != nil {
+			if err := BindFlagSet(fs, tc.sources...); err != nil {
 				t.Fatalf("Failed to bind flag set to environment variables: %v", err)
 			}
 			if err := fs.Parse(tc.cmdLine); err != nil {
@@ -119,7 +121,7 @@ func TestBindErrors(t *testing.T) {
 	t.Run("called after flag.Parse()", func(t *testing.T) {
 		fs := flag.NewFlagSet("test", flag.ContinueOnError)
 		_ = fs.Parse(nil)
-		if err := BindFlagSet(fs, AllEnv); err == nil {
+		if err := BindFlagSet(fs, NoPrefix); err == nil {
 			t.Error("BindFlagSet() was called after flag.Parse() but did not return an error. Expected an error.")
 		}
 	})
@@ -128,7 +130,7 @@ func TestBindErrors(t *testing.T) {
 		fs := flag.NewFlagSet("test", flag.ContinueOnError)
 		intVar := fs.Int("int-var", 20, "an int flag.")
 		os.Setenv("INT_VAR", "not-a-number")
-		err := BindFlagSet(fs, AllEnv)
+		err := BindFlagSet(fs, NoPrefix)
 		if err == nil {
 			t.Fatal("Calling BindFlagSet() with INT_VAR=not-a-number did not return an error. Expected an error.")
 		}
@@ -160,7 +162,7 @@ func TestBindUpdatesUsage(t *testing.T) {
 		os.Clearenv()
 		fs := flag.NewFlagSet("test", flag.ContinueOnError)
 		fs.String("my-var", "default value", "This is a flag.")
-		if err := BindFlagSet(fs, AllEnv); err != nil {
+		if err := BindFlagSet(fs, NoPrefix); err != nil {
 			t.Fatalf("Failed to bind flag set to environment variables: %v", err)
 		}
 		wantUsage := "This is a flag. [MY_VAR]"
@@ -173,7 +175,7 @@ func TestBindUpdatesUsage(t *testing.T) {
 		os.Clearenv()
 		fs := flag.NewFlagSet("test", flag.ContinueOnError)
 		fs.String("my-var", "default value", "This is a flag.")
-		if err := BindFlagSet(fs, AllEnv, NewPrefix("MYAPP", Strict(true))); err != nil {
+		if err := BindFlagSet(fs, NoPrefix, NewPrefix("MYAPP", Strict(true))); err != nil {
 			t.Fatalf("Failed to bind flag set to environment variables: %v", err)
 		}
 		wantUsage := "This is a flag. [MY_VAR, MYAPP_MY_VAR]"
@@ -184,6 +186,216 @@ func TestBindUpdatesUsage(t *testing.T) {
 	})
 }
 
+// stringSlice is a minimal SliceValue implementation used to test envflag's handling of repeated flags.
+type stringSlice []string
+
+func (s *stringSlice) String() string {
+	if s == nil {
+		return ""
+	}
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSlice) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+func (s *stringSlice) Replace(values []string) error {
+	*s = append([]string(nil), values...)
+	return nil
+}
+
+func TestBindSliceValue(t *testing.T) {
+	t.Run("comma-separated environment variable", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("TAGS", "a,b,c")
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		var tags stringSlice
+		fs.Var(&tags, "tags", "Tags to apply.")
+		if err := BindFlagSet(fs, NoPrefix); err != nil {
+			t.Fatalf("Failed to bind flag set: %v", err)
+		}
+		want := []string{"a", "b", "c"}
+		if !reflect.DeepEqual([]string(tags), want) {
+			t.Errorf("--tags flag value = %v, want %v", []string(tags), want)
+		}
+	})
+	t.Run("indexed environment variables", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("TAGS_0", "a")
+		os.Setenv("TAGS_1", "b")
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		var tags stringSlice
+		fs.Var(&tags, "tags", "Tags to apply.")
+		if err := BindFlagSet(fs, NoPrefix); err != nil {
+			t.Fatalf("Failed to bind flag set: %v", err)
+		}
+		want := []string{"a", "b"}
+		if !reflect.DeepEqual([]string(tags), want) {
+			t.Errorf("--tags flag value = %v, want %v", []string(tags), want)
+		}
+	})
+	t.Run("custom separator", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("TAGS", "a|b")
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		var tags stringSlice
+		fs.Var(&tags, "tags", "Tags to apply.")
+		if err := BindFlagSet(fs, NewPrefix("", WithListSeparator("|"))); err != nil {
+			t.Fatalf("Failed to bind flag set: %v", err)
+		}
+		want := []string{"a", "b"}
+		if !reflect.DeepEqual([]string(tags), want) {
+			t.Errorf("--tags flag value = %v, want %v", []string(tags), want)
+		}
+	})
+	t.Run("replaces a non-empty default instead of appending to it", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("TAGS", "debug,warn")
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		tags := stringSlice{"info"}
+		fs.Var(&tags, "tags", "Tags to apply.")
+		if err := BindFlagSet(fs, NoPrefix); err != nil {
+			t.Fatalf("Failed to bind flag set: %v", err)
+		}
+		want := []string{"debug", "warn"}
+		if !reflect.DeepEqual([]string(tags), want) {
+			t.Errorf("--tags flag value = %v, want %v", []string(tags), want)
+		}
+	})
+	t.Run("strict prefix does not flag indexed variables as unknown", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("TAGS_0", "a")
+		os.Setenv("TAGS_1", "b")
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		var tags stringSlice
+		fs.Var(&tags, "tags", "Tags to apply.")
+		if err := BindFlagSet(fs, NewPrefix("", Strict(true))); err != nil {
+			t.Fatalf("BindFlagSet() with a strict prefix and matching indexed variables returned an error: %v", err)
+		}
+		want := []string{"a", "b"}
+		if !reflect.DeepEqual([]string(tags), want) {
+			t.Errorf("--tags flag value = %v, want %v", []string(tags), want)
+		}
+	})
+}
+
+func TestBindErrorConflicts(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("MY_VAR", "value 1")
+	os.Setenv("MYAPP_MY_VAR", "value 2")
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("my-var", "default value", "This is a flag.")
+	err := BindFlagSet(fs, NewPrefix(""), NewPrefix("MYAPP", Strict(true)))
+	if err == nil {
+		t.Fatal("BindFlagSet() with conflicting strict sources did not return an error. Expected an error.")
+	}
+	var bindErr *BindError
+	if !errors.As(err, &bindErr) {
+		t.Fatalf("BindFlagSet() returned %T, want *BindError", err)
+	}
+	var problem *BindProblem
+	for i, p := range bindErr.Problems {
+		if p.Flag == "my-var" {
+			problem = &bindErr.Problems[i]
+		}
+	}
+	if problem == nil {
+		t.Fatalf("BindError.Problems = %v, want a conflict problem for --my-var", bindErr.Problems)
+	}
+	want := `--my-var: conflicting values: "value 1" from environment variable MY_VAR, "value 2" from environment variable MYAPP_MY_VAR`
+	if got := problem.Error(); got != want {
+		t.Errorf("conflict BindProblem.Error() = %q, want %q", got, want)
+	}
+}
+
+func TestPrefixCustomMapping(t *testing.T) {
+	t.Run("custom name mapper", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("MYAPP_Port", "9090")
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		port := fs.String("port", "8080", "Port to listen on.")
+		mapper := func(flagName string) string {
+			return strings.Title(flagName)
+		}
+		if err := BindFlagSet(fs, NewPrefix("MYAPP", WithNameMapper(mapper))); err != nil {
+			t.Fatalf("Failed to bind flag set: %v", err)
+		}
+		if *port != "9090" {
+			t.Errorf("--port flag value = %q, want %q", *port, "9090")
+		}
+	})
+	t.Run("custom separator", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("MYAPP__PORT", "9090")
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		port := fs.String("port", "8080", "Port to listen on.")
+		if err := BindFlagSet(fs, NewPrefix("MYAPP", WithSeparator("__"))); err != nil {
+			t.Fatalf("Failed to bind flag set: %v", err)
+		}
+		if *port != "9090" {
+			t.Errorf("--port flag value = %q, want %q", *port, "9090")
+		}
+	})
+}
+
+func TestPrefixFlagName(t *testing.T) {
+	os.Clearenv()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("my-var", "default value", "This is a flag.")
+	prefix := NewPrefix("MYAPP")
+	if err := BindFlagSet(fs, prefix); err != nil {
+		t.Fatalf("Failed to bind flag set: %v", err)
+	}
+	name, ok := prefix.FlagName("MYAPP_MY_VAR")
+	if !ok || name != "my-var" {
+		t.Errorf("prefix.FlagName(%q) = (%q, %v), want (%q, true)", "MYAPP_MY_VAR", name, ok, "my-var")
+	}
+	if _, ok := prefix.FlagName("MYAPP_NOT_A_FLAG"); ok {
+		t.Errorf("prefix.FlagName(%q) reported a match for an env var with no corresponding flag", "MYAPP_NOT_A_FLAG")
+	}
+}
+
+func TestBindFlagSetAfterParse(t *testing.T) {
+	t.Run("command line overrides environment", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("MY_VAR", "env value")
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		testString := fs.String("my-var", "default value", "This is a flag.")
+		if err := fs.Parse([]string{"--my-var=cmd value"}); err != nil {
+			t.Fatalf("Failed to parse flags: %v", err)
+		}
+		if err := BindFlagSetAfterParse(fs, NoPrefix); err != nil {
+			t.Fatalf("Failed to bind flag set to environment variables: %v", err)
+		}
+		if *testString != "cmd value" {
+			t.Errorf("--my-var flag value = %q, want %q", *testString, "cmd value")
+		}
+	})
+	t.Run("environment fills unset flags", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("MY_VAR", "env value")
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		testString := fs.String("my-var", "default value", "This is a flag.")
+		if err := fs.Parse(nil); err != nil {
+			t.Fatalf("Failed to parse flags: %v", err)
+		}
+		if err := BindFlagSetAfterParse(fs, NoPrefix); err != nil {
+			t.Fatalf("Failed to bind flag set to environment variables: %v", err)
+		}
+		if *testString != "env value" {
+			t.Errorf("--my-var flag value = %q, want %q", *testString, "env value")
+		}
+	})
+	t.Run("called before flag.Parse()", func(t *testing.T) {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		if err := BindFlagSetAfterParse(fs, NoPrefix); err == nil {
+			t.Error("BindFlagSetAfterParse() was called before flag.Parse() but did not return an error. Expected an error.")
+		}
+	})
+}
+
 func TestBindErrorHandling(t *testing.T) {
 	var exited bool
 	exit = func() {
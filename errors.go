@@ -0,0 +1,106 @@
+// Copyright 2020 Google LLC
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envflag
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// BindProblem describes a single issue found while applying a Source to a flag set: an invalid value, a conflict
+// between sources, or an unknown environment variable under a strict prefix.
+type BindProblem struct {
+	// Flag is the name of the flag the problem relates to, or "" for problems not tied to a single flag (e.g. an
+	// unknown strict environment variable).
+	Flag string
+	// Source identifies the source involved, e.g. "environment variable MYAPP_PORT".
+	Source string
+	// Value is the offending raw value, if any.
+	Value string
+	// Err is the underlying cause.
+	Err error
+}
+
+func (p BindProblem) Error() string {
+	switch {
+	case p.Flag != "" && p.Value != "":
+		return fmt.Sprintf("invalid value %q for %s: %v", p.Value, p.Source, p.Err)
+	case p.Flag != "":
+		return fmt.Sprintf("--%s: %v", p.Flag, p.Err)
+	default:
+		return p.Err.Error()
+	}
+}
+
+// BindError is returned by BindFlagSet and BindFlagSetAfterParse when one or more problems occur while applying
+// sources to a flag set. It collects every problem found instead of stopping at the first, so callers can present
+// all of them at once, or use errors.As with a *envflag.BindError target to inspect them programmatically.
+type BindError struct {
+	// Problems enumerates every issue encountered: failures to load a source first, then problems with individual
+	// flags in the order the flags were visited, then unknown environment variables under strict prefixes.
+	Problems []BindProblem
+}
+
+func (e *BindError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		msgs[i] = p.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// sourceContribution records that source provided value for a flag, so conflicts between sources can be detected
+// after every source has been consulted.
+type sourceContribution struct {
+	source Source
+	value  string
+}
+
+// conflictProblem reports a BindProblem when two or more sources provided different values for f and at least one
+// of the contributing sources is a strict Prefix. Outside of strict mode, the existing last-source-wins precedence
+// is left to resolve silently, matching the long-standing multiple-prefixes behavior.
+func conflictProblem(f *flag.Flag, contribs []sourceContribution) *BindProblem {
+	if len(contribs) < 2 {
+		return nil
+	}
+	strict := false
+	for _, c := range contribs {
+		if p, ok := c.source.(*Prefix); ok && p.strict {
+			strict = true
+		}
+	}
+	if !strict {
+		return nil
+	}
+	first := contribs[0].value
+	conflicting := false
+	for _, c := range contribs[1:] {
+		if c.value != first {
+			conflicting = true
+		}
+	}
+	if !conflicting {
+		return nil
+	}
+	parts := make([]string, len(contribs))
+	for i, c := range contribs {
+		parts[i] = fmt.Sprintf("%q from %s", c.value, c.source.describe(f))
+	}
+	return &BindProblem{
+		Flag: f.Name,
+		Err:  fmt.Errorf("conflicting values: %s", strings.Join(parts, ", ")),
+	}
+}
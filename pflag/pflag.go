@@ -0,0 +1,94 @@
+// Copyright 2020 Google LLC
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pflag adapts envflag's environment-variable binding to github.com/spf13/pflag.FlagSet, for applications
+// built on pflag or cobra rather than the standard library flag package.
+package pflag
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kevinmdavis/envflag"
+	"github.com/spf13/pflag"
+)
+
+// BindPFlagSet binds environment variables to the provided pflag.FlagSet, following the same precedence and strict
+// mode semantics as envflag.BindFlagSet: later prefixes win, and a strict prefix reports any environment variable
+// under it that doesn't correspond to a defined flag. It should be called before fs.Parse(), so that command-line
+// flags retain precedence; a flag already marked Changed (e.g. set on the command line) is left untouched. Values
+// applied from the environment do not mark a flag Changed, and each call appends the environment variable names
+// to every flag's usage string again. Shorthand flags are bound under their long name only.
+func BindPFlagSet(fs *pflag.FlagSet, prefixes ...*envflag.Prefix) error {
+	if len(prefixes) == 0 {
+		prefixes = []*envflag.Prefix{envflag.NoPrefix}
+	}
+	matched := make(map[string]bool)
+	var problems []envflag.BindProblem
+	fs.VisitAll(func(f *pflag.Flag) {
+		names := make([]string, len(prefixes))
+		for i, p := range prefixes {
+			names[i] = p.EnvName(f.Name)
+		}
+		f.Usage = fmt.Sprintf("%s [%s]", f.Usage, strings.Join(names, ", "))
+		if f.Changed {
+			return
+		}
+		sliceValue, isSlice := f.Value.(pflag.SliceValue)
+		for _, p := range prefixes {
+			source := "environment variable " + p.EnvName(f.Name)
+			if isSlice {
+				elems, ok := p.LookupSlice(f.Name)
+				if !ok {
+					continue
+				}
+				for _, name := range p.SliceEnvNames(f.Name) {
+					matched[name] = true
+				}
+				if err := sliceValue.Replace(elems); err != nil {
+					problems = append(problems, envflag.BindProblem{
+						Flag: f.Name, Source: source, Value: strings.Join(elems, ","), Err: err,
+					})
+				}
+				continue
+			}
+			value, ok := p.Lookup(f.Name)
+			if !ok {
+				continue
+			}
+			matched[p.EnvName(f.Name)] = true
+			if err := f.Value.Set(value); err != nil {
+				problems = append(problems, envflag.BindProblem{Flag: f.Name, Source: source, Value: value, Err: err})
+			}
+		}
+	})
+	for _, e := range os.Environ() {
+		for _, p := range prefixes {
+			if !p.IsStrict() {
+				continue
+			}
+			envName := strings.Split(e, "=")[0]
+			if p.HasEnvPrefix(e) && !matched[envName] {
+				problems = append(problems, envflag.BindProblem{
+					Err: fmt.Errorf("environment variable provided but corresponding flag not defined: %s", envName),
+				})
+			}
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return &envflag.BindError{Problems: problems}
+}
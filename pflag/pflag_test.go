@@ -0,0 +1,104 @@
+// Copyright 2020 Google LLC
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pflag
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/kevinmdavis/envflag"
+	"github.com/spf13/pflag"
+)
+
+func TestBindPFlagSet(t *testing.T) {
+	t.Run("value from environment", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("MY_VAR", "env value")
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		testString := fs.String("my-var", "default value", "This is a flag.")
+		if err := BindPFlagSet(fs, envflag.NoPrefix); err != nil {
+			t.Fatalf("Failed to bind flag set to environment variables: %v", err)
+		}
+		if *testString != "env value" {
+			t.Errorf("--my-var flag value = %q, want %q", *testString, "env value")
+		}
+	})
+	t.Run("command line takes precedence when already parsed", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("MY_VAR", "env value")
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		testString := fs.String("my-var", "default value", "This is a flag.")
+		if err := fs.Parse([]string{"--my-var=cmd value"}); err != nil {
+			t.Fatalf("Failed to parse flags: %v", err)
+		}
+		if err := BindPFlagSet(fs, envflag.NoPrefix); err != nil {
+			t.Fatalf("Failed to bind flag set to environment variables: %v", err)
+		}
+		if *testString != "cmd value" {
+			t.Errorf("--my-var flag value = %q, want %q", *testString, "cmd value")
+		}
+	})
+	t.Run("strict prefix reports unknown variable", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("MYAPP_BAD_VAR", "value")
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		err := BindPFlagSet(fs, envflag.NewPrefix("MYAPP", envflag.Strict(true)))
+		if err == nil {
+			t.Fatal("BindPFlagSet() with an unknown variable under a strict prefix did not return an error. Expected an error.")
+		}
+	})
+	t.Run("comma-separated environment variable for a slice flag", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("TAGS", "a,b,c")
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		tags := fs.StringSlice("tags", []string{"default"}, "Tags to apply.")
+		if err := BindPFlagSet(fs, envflag.NoPrefix); err != nil {
+			t.Fatalf("Failed to bind flag set to environment variables: %v", err)
+		}
+		want := []string{"a", "b", "c"}
+		if !reflect.DeepEqual(*tags, want) {
+			t.Errorf("--tags flag value = %v, want %v", *tags, want)
+		}
+	})
+	t.Run("indexed environment variables for a slice flag", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("TAGS_0", "a")
+		os.Setenv("TAGS_1", "b")
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		tags := fs.StringSlice("tags", nil, "Tags to apply.")
+		if err := BindPFlagSet(fs, envflag.NoPrefix); err != nil {
+			t.Fatalf("Failed to bind flag set to environment variables: %v", err)
+		}
+		want := []string{"a", "b"}
+		if !reflect.DeepEqual(*tags, want) {
+			t.Errorf("--tags flag value = %v, want %v", *tags, want)
+		}
+	})
+	t.Run("strict prefix does not flag indexed slice variables as unknown", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("MYAPP_TAGS_0", "a")
+		os.Setenv("MYAPP_TAGS_1", "b")
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		tags := fs.StringSlice("tags", nil, "Tags to apply.")
+		if err := BindPFlagSet(fs, envflag.NewPrefix("MYAPP", envflag.Strict(true))); err != nil {
+			t.Fatalf("BindPFlagSet() with a strict prefix and matching indexed variables returned an error: %v", err)
+		}
+		want := []string{"a", "b"}
+		if !reflect.DeepEqual(*tags, want) {
+			t.Errorf("--tags flag value = %v, want %v", *tags, want)
+		}
+	})
+}
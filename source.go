@@ -0,0 +1,145 @@
+// Copyright 2020 Google LLC
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envflag
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FileFormat identifies how a configuration file passed to FromFile should be decoded.
+type FileFormat int
+
+const (
+	// FormatAuto infers the format from the file's extension (.yaml/.yml, .json, or .toml).
+	FormatAuto FileFormat = iota
+	FormatYAML
+	FormatJSON
+	FormatTOML
+)
+
+// FileOption configures a Source created by FromFile.
+type FileOption func(*fileSource)
+
+// WithFormat overrides the format inferred from a config file's extension.
+func WithFormat(format FileFormat) FileOption {
+	return func(s *fileSource) {
+		s.format = format
+	}
+}
+
+// FromFile returns a Source that reads flag values from a YAML, JSON, or TOML configuration file. The file must
+// decode to a flat map of keys to scalar values, or to lists for SliceValue flags. A flag's name is translated to its key in the file using the same
+// kebab-case -> UPPER_SNAKE rule used for environment variables (e.g. the flag "my-var" reads the key "MY_VAR").
+// The file is read and parsed when BindFlagSet is called, not when FromFile is called, so parse errors are reported
+// through the same flag.ErrorHandling path as any other binding error.
+func FromFile(path string, opts ...FileOption) Source {
+	s := &fileSource{path: path, format: FormatAuto}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+type fileSource struct {
+	path   string
+	format FileFormat
+	values map[string]interface{}
+}
+
+func (s *fileSource) prepare() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("envflag: reading %s: %w", s.path, err)
+	}
+	format := s.format
+	if format == FormatAuto {
+		format = detectFormat(s.path)
+	}
+	raw := make(map[string]interface{})
+	switch format {
+	case FormatYAML:
+		err = yaml.Unmarshal(data, &raw)
+	case FormatJSON:
+		err = json.Unmarshal(data, &raw)
+	case FormatTOML:
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return fmt.Errorf("envflag: %s: unrecognized config file format, set a format with WithFormat", s.path)
+	}
+	if err != nil {
+		return fmt.Errorf("envflag: parsing %s: %w", s.path, err)
+	}
+	s.values = raw
+	return nil
+}
+
+func (s *fileSource) key(f *flag.Flag) string {
+	return defaultNameMapper(f.Name)
+}
+
+func (s *fileSource) lookup(f *flag.Flag) (string, bool) {
+	v, ok := s.values[s.key(f)]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%v", v), true
+}
+
+// lookupSlice returns a file value's elements for a SliceValue flag. A native list (e.g. YAML/JSON/TOML array) is
+// used as-is; any other value is stringified and split on commas, so a config author can also write a flat
+// comma-separated string (e.g. "a,b").
+func (s *fileSource) lookupSlice(f *flag.Flag) ([]string, bool) {
+	v, ok := s.values[s.key(f)]
+	if !ok {
+		return nil, false
+	}
+	if list, ok := v.([]interface{}); ok {
+		elems := make([]string, len(list))
+		for i, e := range list {
+			elems[i] = fmt.Sprintf("%v", e)
+		}
+		return elems, true
+	}
+	return strings.Split(fmt.Sprintf("%v", v), ","), true
+}
+
+func (s *fileSource) usageName(f *flag.Flag) string {
+	return fmt.Sprintf("%s:%s", filepath.Base(s.path), s.key(f))
+}
+
+func (s *fileSource) describe(f *flag.Flag) string {
+	return fmt.Sprintf("file %s key %s", s.path, s.key(f))
+}
+
+func detectFormat(path string) FileFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".json":
+		return FormatJSON
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatAuto
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2020 Google LLC
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// https://www.apache.org/licenses/LICENSE-2.0
+
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envflag
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTempFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+	return path
+}
+
+func TestFromFile(t *testing.T) {
+	tests := []struct {
+		description string
+		fileName    string
+		contents    string
+		want        string
+	}{
+		{
+			description: "yaml",
+			fileName:    "config.yaml",
+			contents:    "MY_VAR: from yaml\n",
+			want:        "from yaml",
+		},
+		{
+			description: "json",
+			fileName:    "config.json",
+			contents:    `{"MY_VAR": "from json"}`,
+			want:        "from json",
+		},
+		{
+			description: "toml",
+			fileName:    "config.toml",
+			contents:    `MY_VAR = "from toml"`,
+			want:        "from toml",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			os.Clearenv()
+			path := writeTempFile(t, tc.fileName, tc.contents)
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			testString := fs.String("my-var", "default value", "This is a flag.")
+			if err := BindFlagSet(fs, FromFile(path)); err != nil {
+				t.Fatalf("Failed to bind flag set to file source: %v", err)
+			}
+			if *testString != tc.want {
+				t.Errorf("--my-var flag value = %q, want %q", *testString, tc.want)
+			}
+		})
+	}
+}
+
+func TestFromFilePrecedence(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("MY_VAR", "env value")
+	path := writeTempFile(t, "config.yaml", "MY_VAR: file value\n")
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	testString := fs.String("my-var", "default value", "This is a flag.")
+	if err := BindFlagSet(fs, FromFile(path), FromEnv(NoPrefix)); err != nil {
+		t.Fatalf("Failed to bind flag set: %v", err)
+	}
+	// FromEnv is listed after FromFile, so it should win.
+	if *testString != "env value" {
+		t.Errorf("--my-var flag value = %q, want %q", *testString, "env value")
+	}
+}
+
+func TestFromFileSliceValue(t *testing.T) {
+	tests := []struct {
+		description string
+		fileName    string
+		contents    string
+	}{
+		{
+			description: "yaml list",
+			fileName:    "config.yaml",
+			contents:    "TAGS: [a, b, c]\n",
+		},
+		{
+			description: "json list",
+			fileName:    "config.json",
+			contents:    `{"TAGS": ["a", "b", "c"]}`,
+		},
+		{
+			description: "toml list",
+			fileName:    "config.toml",
+			contents:    `TAGS = ["a", "b", "c"]`,
+		},
+		{
+			description: "comma-separated string",
+			fileName:    "config.yaml",
+			contents:    "TAGS: a,b,c\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			os.Clearenv()
+			path := writeTempFile(t, tc.fileName, tc.contents)
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			var tags stringSlice
+			fs.Var(&tags, "tags", "Tags to apply.")
+			if err := BindFlagSet(fs, FromFile(path)); err != nil {
+				t.Fatalf("Failed to bind flag set to file source: %v", err)
+			}
+			want := []string{"a", "b", "c"}
+			if !reflect.DeepEqual([]string(tags), want) {
+				t.Errorf("--tags flag value = %v, want %v", []string(tags), want)
+			}
+		})
+	}
+}
+
+func TestFromFileMissing(t *testing.T) {
+	os.Clearenv()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("my-var", "default value", "This is a flag.")
+	err := BindFlagSet(fs, FromFile("/does/not/exist.yaml"))
+	if err == nil {
+		t.Fatal("BindFlagSet() with a missing config file did not return an error. Expected an error.")
+	}
+	var bindErr *BindError
+	if !errors.As(err, &bindErr) {
+		t.Fatalf("BindFlagSet() returned %T, want *BindError", err)
+	}
+	if len(bindErr.Problems) != 1 {
+		t.Errorf("BindError.Problems = %v, want exactly one problem", bindErr.Problems)
+	}
+}
+
+func TestFromFileMissingCollectsEveryFailure(t *testing.T) {
+	os.Clearenv()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	testString := fs.String("my-var", "default value", "This is a flag.")
+	path := writeTempFile(t, "config.yaml", "MY_VAR: from yaml\n")
+	err := BindFlagSet(fs, FromFile("/does/not/exist/one.yaml"), FromFile(path), FromFile("/does/not/exist/two.yaml"))
+	if err == nil {
+		t.Fatal("BindFlagSet() with two missing config files did not return an error. Expected an error.")
+	}
+	var bindErr *BindError
+	if !errors.As(err, &bindErr) {
+		t.Fatalf("BindFlagSet() returned %T, want *BindError", err)
+	}
+	if len(bindErr.Problems) != 2 {
+		t.Errorf("BindError.Problems = %v, want exactly two problems, one per missing file", bindErr.Problems)
+	}
+	// The failing sources shouldn't prevent the still-valid source in between from being applied.
+	if *testString != "from yaml" {
+		t.Errorf("--my-var flag value = %q, want %q", *testString, "from yaml")
+	}
+}